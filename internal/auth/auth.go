@@ -0,0 +1,72 @@
+// Package auth implements the faucet's pluggable authentication modes.
+// Exactly one Handler is active at a time, selected via --auth-mode, so that
+// operators can gate access behind a shared secret, a trusted reverse-proxy
+// header, or a minimal OAuth2 code flow without the faucet caring which one
+// is in effect.
+package auth
+
+import (
+	"context"
+	"net/http"
+)
+
+// Identity is the authenticated caller attached to a request once a Handler
+// accepts it. The faucet records Subject alongside each channel-open and
+// invoice-pay in its existing tracking so the same user can't repeatedly
+// claim from different IPs.
+type Identity struct {
+	// Subject uniquely identifies the caller within Mode, e.g. the
+	// shared-secret token's subject claim, the trusted header's value,
+	// or the OAuth provider's account ID.
+	Subject string
+
+	// Mode is the name of the AuthHandler that produced this Identity
+	// ("sharedsecret", "httpheader", or "oauth").
+	Mode string
+}
+
+// AuthHandler authenticates incoming requests for one auth mode.
+type AuthHandler interface {
+	// Authenticate inspects r and returns the caller's Identity. ok is
+	// false when the request carries no credentials at all (the caller
+	// should be rejected as unauthenticated); a non-nil err with ok true
+	// indicates credentials were present but invalid or expired.
+	Authenticate(r *http.Request) (id *Identity, ok bool, err error)
+}
+
+type identityKey struct{}
+
+// withIdentity returns a copy of ctx carrying id.
+func withIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the Identity attached to ctx by Middleware, if any.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	return id, ok
+}
+
+// Middleware authenticates every request with handler before delegating to
+// next, rejecting unauthenticated or invalid requests with 401. It's meant
+// to be registered ahead of the faucet's route table via r.Use, per
+// --auth-mode; pass a nil handler (the "none" mode) to disable gating
+// entirely.
+func Middleware(handler AuthHandler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if handler == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, ok, err := handler.Authenticate(r)
+			if err != nil || !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			r = r.WithContext(withIdentity(r.Context(), id))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"fmt"
+	"net"
+)
+
+// Config selects and parameterizes one of the faucet's auth modes.
+type Config struct {
+	// Mode is one of "none", "sharedsecret", "httpheader", or "oauth".
+	Mode string
+
+	// SharedSecret is the HMAC key used in "sharedsecret" mode.
+	SharedSecret []byte
+
+	// HeaderName and TrustedProxies configure "httpheader" mode.
+	HeaderName     string
+	TrustedProxies []string
+
+	// OAuth configures "oauth" mode.
+	OAuth          OAuthProvider
+	OAuthCookie    string
+	OAuthCookieKey []byte
+}
+
+// New builds the AuthHandler selected by cfg.Mode. It returns a nil handler
+// (and nil error) for Mode "none", since Middleware treats a nil handler as
+// "no gating".
+func New(cfg Config) (AuthHandler, error) {
+	switch cfg.Mode {
+	case "", "none":
+		return nil, nil
+
+	case "sharedsecret":
+		if len(cfg.SharedSecret) == 0 {
+			return nil, fmt.Errorf("sharedsecret auth mode requires a secret")
+		}
+		return NewSharedSecretHandler(cfg.SharedSecret), nil
+
+	case "httpheader":
+		proxies, err := parseCIDRs(cfg.TrustedProxies)
+		if err != nil {
+			return nil, err
+		}
+		return NewHTTPHeaderHandler(cfg.HeaderName, proxies), nil
+
+	case "oauth":
+		return NewOAuthHandler(cfg.OAuth, cfg.OAuthCookie, cfg.OAuthCookieKey), nil
+
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q", cfg.Mode)
+	}
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy CIDR %q: %v", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"net"
+	"net/http"
+)
+
+// HTTPHeaderHandler authenticates requests by trusting a header populated by
+// an upstream reverse proxy (e.g. "X-Faucet-User"). To prevent a direct
+// client from forging the header, it's only honored when the immediate peer
+// address falls within TrustedProxies.
+type HTTPHeaderHandler struct {
+	Header         string
+	TrustedProxies []*net.IPNet
+}
+
+// NewHTTPHeaderHandler creates a handler that trusts header when the request
+// arrives from one of trustedProxies.
+func NewHTTPHeaderHandler(header string, trustedProxies []*net.IPNet) *HTTPHeaderHandler {
+	return &HTTPHeaderHandler{Header: header, TrustedProxies: trustedProxies}
+}
+
+// Authenticate implements the AuthHandler interface.
+func (h *HTTPHeaderHandler) Authenticate(r *http.Request) (*Identity, bool, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	peer := net.ParseIP(host)
+	if peer == nil || !h.isTrustedProxy(peer) {
+		return nil, false, nil
+	}
+
+	value := r.Header.Get(h.Header)
+	if value == "" {
+		return nil, false, nil
+	}
+
+	return &Identity{Subject: value, Mode: "httpheader"}, true, nil
+}
+
+func (h *HTTPHeaderHandler) isTrustedProxy(ip net.IP) bool {
+	for _, cidr := range h.TrustedProxies {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func trustedCIDR(t *testing.T, cidr string) *net.IPNet {
+	t.Helper()
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatalf("ParseCIDR(%q) error = %v", cidr, err)
+	}
+	return n
+}
+
+func TestHTTPHeaderHandlerTrustedProxy(t *testing.T) {
+	h := NewHTTPHeaderHandler("X-Faucet-User", []*net.IPNet{trustedCIDR(t, "10.0.0.0/8")})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.1.2.3:5555"
+	req.Header.Set("X-Faucet-User", "alice")
+
+	id, ok, err := h.Authenticate(req)
+	if err != nil || !ok {
+		t.Fatalf("Authenticate() = %+v, %v, %v", id, ok, err)
+	}
+	if id.Subject != "alice" || id.Mode != "httpheader" {
+		t.Fatalf("Identity = %+v, want Subject=alice Mode=httpheader", id)
+	}
+}
+
+func TestHTTPHeaderHandlerRejectsUntrustedPeer(t *testing.T) {
+	h := NewHTTPHeaderHandler("X-Faucet-User", []*net.IPNet{trustedCIDR(t, "10.0.0.0/8")})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.1:5555"
+	req.Header.Set("X-Faucet-User", "alice")
+
+	_, ok, err := h.Authenticate(req)
+	if ok || err != nil {
+		t.Fatalf("Authenticate() = ok %v, err %v, want ok=false err=nil", ok, err)
+	}
+}
+
+func TestHTTPHeaderHandlerRejectsMissingHeader(t *testing.T) {
+	h := NewHTTPHeaderHandler("X-Faucet-User", []*net.IPNet{trustedCIDR(t, "10.0.0.0/8")})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.1.2.3:5555"
+
+	_, ok, err := h.Authenticate(req)
+	if ok || err != nil {
+		t.Fatalf("Authenticate() = ok %v, err %v, want ok=false err=nil", ok, err)
+	}
+}
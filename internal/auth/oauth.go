@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// oauthHTTPTimeout bounds the token-exchange and userinfo requests made by
+// exchangeAndFetchSubject, so a slow or unresponsive provider can't hang the
+// callback handler indefinitely.
+const oauthHTTPTimeout = 10 * time.Second
+
+var oauthHTTPClient = &http.Client{Timeout: oauthHTTPTimeout}
+
+// OAuthProvider carries the endpoints and credentials for a single OAuth2
+// provider (GitHub, Matrix, etc.) configured for --auth-mode=oauth.
+type OAuthProvider struct {
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	RedirectURL  string
+	Scope        string
+}
+
+// OAuthHandler performs a minimal OAuth2 authorization-code flow and stores
+// the result in a signed cookie, reusing SharedSecretHandler's token format
+// so the cookie itself is a verifiable, expiring credential.
+type OAuthHandler struct {
+	Provider   OAuthProvider
+	CookieName string
+
+	tokens *SharedSecretHandler
+}
+
+// NewOAuthHandler creates a handler for provider, signing session cookies
+// with cookieSecret.
+func NewOAuthHandler(provider OAuthProvider, cookieName string, cookieSecret []byte) *OAuthHandler {
+	return &OAuthHandler{
+		Provider:   provider,
+		CookieName: cookieName,
+		tokens:     NewSharedSecretHandler(cookieSecret),
+	}
+}
+
+// Authenticate implements the AuthHandler interface by validating the
+// signed session cookie set by CallbackHandler.
+func (h *OAuthHandler) Authenticate(r *http.Request) (*Identity, bool, error) {
+	cookie, err := r.Cookie(h.CookieName)
+	if err != nil || cookie.Value == "" {
+		return nil, false, nil
+	}
+
+	id, err := h.tokens.verify(cookie.Value)
+	if err != nil {
+		return nil, true, err
+	}
+	id.Mode = "oauth"
+	return id, true, nil
+}
+
+// LoginHandler redirects the browser to the provider's authorization
+// endpoint to kick off the code flow.
+func (h *OAuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	state, err := randomState()
+	if err != nil {
+		http.Error(w, "unable to start login", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     h.CookieName + "_state",
+		Value:    state,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int((10 * time.Minute).Seconds()),
+	})
+
+	q := url.Values{}
+	q.Set("client_id", h.Provider.ClientID)
+	q.Set("redirect_uri", h.Provider.RedirectURL)
+	q.Set("scope", h.Provider.Scope)
+	q.Set("state", state)
+	q.Set("response_type", "code")
+
+	http.Redirect(w, r, h.Provider.AuthURL+"?"+q.Encode(), http.StatusFound)
+}
+
+// CallbackHandler exchanges the authorization code for an access token,
+// fetches the provider's user-info endpoint, and sets a signed session
+// cookie identifying the caller.
+func (h *OAuthHandler) CallbackHandler(w http.ResponseWriter, r *http.Request) {
+	stateCookie, err := r.Cookie(h.CookieName + "_state")
+	if err != nil || stateCookie.Value != r.URL.Query().Get("state") {
+		http.Error(w, "invalid oauth state", http.StatusBadRequest)
+		return
+	}
+
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		http.Error(w, "missing oauth code", http.StatusBadRequest)
+		return
+	}
+
+	subject, err := h.exchangeAndFetchSubject(r.Context(), code)
+	if err != nil {
+		http.Error(w, "oauth login failed", http.StatusBadGateway)
+		return
+	}
+
+	token, err := h.tokens.Sign(subject, 24*time.Hour)
+	if err != nil {
+		http.Error(w, "unable to complete login", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     h.CookieName,
+		Value:    token,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int((24 * time.Hour).Seconds()),
+	})
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// exchangeAndFetchSubject performs the token exchange and user-info lookup
+// against the configured provider, returning a stable subject identifier.
+// Both requests run under ctx with oauthHTTPTimeout, so a slow or
+// unresponsive provider can't hang the callback handler indefinitely.
+func (h *OAuthHandler) exchangeAndFetchSubject(ctx context.Context, code string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, oauthHTTPTimeout)
+	defer cancel()
+
+	form := url.Values{
+		"client_id":     {h.Provider.ClientID},
+		"client_secret": {h.Provider.ClientSecret},
+		"code":          {code},
+		"redirect_uri":  {h.Provider.RedirectURL},
+		"grant_type":    {"authorization_code"},
+	}
+	tokenReq, err := http.NewRequestWithContext(ctx, "POST", h.Provider.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := oauthHTTPClient.Do(tokenReq)
+	if err != nil {
+		return "", fmt.Errorf("token exchange failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("unable to decode token response: %v", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", fmt.Errorf("token response missing access_token")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", h.Provider.UserInfoURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+tokenResp.AccessToken)
+
+	userResp, err := oauthHTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("userinfo request failed: %v", err)
+	}
+	defer userResp.Body.Close()
+
+	body, err := ioutil.ReadAll(userResp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var userInfo struct {
+		ID    json.Number `json:"id"`
+		Login string      `json:"login"`
+	}
+	if err := json.Unmarshal(body, &userInfo); err != nil {
+		return "", fmt.Errorf("unable to decode userinfo response: %v", err)
+	}
+	if userInfo.Login != "" {
+		return userInfo.Login, nil
+	}
+	return userInfo.ID.String(), nil
+}
+
+func randomState() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestOAuthHandler(tokenURL, userInfoURL string) *OAuthHandler {
+	return NewOAuthHandler(OAuthProvider{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		AuthURL:      "https://provider.example/authorize",
+		TokenURL:     tokenURL,
+		UserInfoURL:  userInfoURL,
+		RedirectURL:  "https://faucet.example/oauth/callback",
+		Scope:        "profile",
+	}, "faucet_session", []byte("test-secret"))
+}
+
+func TestOAuthHandlerLoginSetsSecureStateCookie(t *testing.T) {
+	h := newTestOAuthHandler("https://provider.example/token", "https://provider.example/userinfo")
+
+	rec := httptest.NewRecorder()
+	h.LoginHandler(rec, httptest.NewRequest(http.MethodGet, "/oauth/login", nil))
+
+	resp := rec.Result()
+	var state *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == h.CookieName+"_state" {
+			state = c
+		}
+	}
+	if state == nil {
+		t.Fatalf("no state cookie set")
+	}
+	if !state.Secure || state.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("state cookie = %+v, want Secure=true SameSite=Lax", state)
+	}
+}
+
+func TestOAuthHandlerCallbackRejectsMismatchedState(t *testing.T) {
+	h := newTestOAuthHandler("https://provider.example/token", "https://provider.example/userinfo")
+
+	req := httptest.NewRequest(http.MethodGet, "/oauth/callback?state=wrong&code=abc", nil)
+	req.AddCookie(&http.Cookie{Name: h.CookieName + "_state", Value: "right"})
+
+	rec := httptest.NewRecorder()
+	h.CallbackHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestOAuthHandlerExchangeAndFetchSubjectRoundTrip(t *testing.T) {
+	userInfo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-access-token" {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(struct {
+			Login string `json:"login"`
+		}{Login: "alice"})
+	}))
+	defer userInfo.Close()
+
+	token := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.FormValue("code") != "the-code" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(struct {
+			AccessToken string `json:"access_token"`
+		}{AccessToken: "test-access-token"})
+	}))
+	defer token.Close()
+
+	h := newTestOAuthHandler(token.URL, userInfo.URL)
+
+	subject, err := h.exchangeAndFetchSubject(context.Background(), "the-code")
+	if err != nil {
+		t.Fatalf("exchangeAndFetchSubject() error = %v", err)
+	}
+	if subject != "alice" {
+		t.Fatalf("subject = %q, want \"alice\"", subject)
+	}
+}
+
+func TestOAuthHandlerExchangeAndFetchSubjectRespectsContext(t *testing.T) {
+	blocked := make(chan struct{})
+	defer close(blocked)
+
+	token := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-blocked
+	}))
+	defer token.Close()
+
+	h := newTestOAuthHandler(token.URL, "https://provider.example/userinfo")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := h.exchangeAndFetchSubject(ctx, "the-code"); err == nil {
+		t.Fatalf("exchangeAndFetchSubject() error = nil, want non-nil for a canceled context")
+	}
+}
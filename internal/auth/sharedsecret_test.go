@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSharedSecretHandlerRoundTrip(t *testing.T) {
+	h := NewSharedSecretHandler([]byte("test-secret"))
+
+	token, err := h.Sign("alice", time.Hour)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?token="+token, nil)
+	id, ok, err := h.Authenticate(req)
+	if err != nil || !ok {
+		t.Fatalf("Authenticate() = %+v, %v, %v", id, ok, err)
+	}
+	if id.Subject != "alice" {
+		t.Fatalf("Subject = %q, want \"alice\"", id.Subject)
+	}
+}
+
+func TestSharedSecretHandlerRejectsExpired(t *testing.T) {
+	h := NewSharedSecretHandler([]byte("test-secret"))
+
+	token, err := h.Sign("alice", -time.Minute)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	_, ok, err := h.Authenticate(req)
+	if !ok || err == nil {
+		t.Fatalf("Authenticate() with expired token = ok %v, err %v, want ok=true err!=nil", ok, err)
+	}
+}
+
+func TestSharedSecretHandlerRejectsTamperedSignature(t *testing.T) {
+	h := NewSharedSecretHandler([]byte("test-secret"))
+
+	token, err := h.Sign("alice", time.Hour)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	tampered := token + "ff"
+
+	req := httptest.NewRequest(http.MethodGet, "/?token="+tampered, nil)
+	_, ok, err := h.Authenticate(req)
+	if !ok || err == nil {
+		t.Fatalf("Authenticate() with tampered token = ok %v, err %v, want ok=true err!=nil", ok, err)
+	}
+}
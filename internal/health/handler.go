@@ -0,0 +1,31 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// LivezHandler always reports the process as up: it doesn't touch lnd at
+// all, so a liveness probe hitting it can't be blocked by a stalled gRPC
+// connection.
+func LivezHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Result{
+		OK:     true,
+		Checks: []SubCheck{{Name: "process", OK: true}},
+	})
+}
+
+// ReadyzHandler reports whether the faucet is ready to serve traffic,
+// per Checker.Readiness, returning 503 when it's not.
+func ReadyzHandler(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result := checker.Readiness(r.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		if !result.OK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(result)
+	}
+}
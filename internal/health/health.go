@@ -0,0 +1,136 @@
+// Package health implements the faucet's /healthz and /readyz endpoints.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LndStatus is the subset of lnd's state the readiness check cares about.
+type LndStatus struct {
+	// Connected reports whether the gRPC connection to lnd is up.
+	Connected bool
+
+	// SyncedToChain mirrors lnrpc.GetInfoResponse.SyncedToChain.
+	SyncedToChain bool
+
+	// ConfirmedBalanceSats is the wallet's on-chain confirmed balance.
+	ConfirmedBalanceSats int64
+
+	// PendingChannels is the number of channels not yet active.
+	PendingChannels int
+}
+
+// StatusProvider is implemented by the faucet to report its current view of
+// lnd, without the health package needing to know about lnrpc directly.
+type StatusProvider interface {
+	LndStatus(ctx context.Context) (*LndStatus, error)
+}
+
+// Thresholds configures when the faucet is considered ready.
+type Thresholds struct {
+	// MinReadyBalanceSats is the minimum confirmed on-chain balance
+	// required to report ready, set via --min-ready-balance.
+	MinReadyBalanceSats int64
+
+	// MaxPendingChannels is the maximum number of pending channels
+	// allowed before the faucet reports not-ready, set via
+	// --max-pending-channels.
+	MaxPendingChannels int
+}
+
+// SubCheck is a single named component of a Checker's result.
+type SubCheck struct {
+	Name string `json:"name"`
+	OK   bool   `json:"ok"`
+	Info string `json:"info,omitempty"`
+}
+
+// Result is the outcome of a readiness evaluation.
+type Result struct {
+	OK     bool       `json:"ok"`
+	Checks []SubCheck `json:"checks"`
+}
+
+// Checker evaluates faucet readiness against a StatusProvider, caching the
+// result for CacheTTL to avoid hammering lnd on every load-balancer probe.
+type Checker struct {
+	provider   StatusProvider
+	thresholds Thresholds
+	cacheTTL   time.Duration
+
+	mu       sync.Mutex
+	cached   *Result
+	cachedAt time.Time
+}
+
+// NewChecker creates a Checker that consults provider for lnd state,
+// evaluates it against thresholds, and caches results for cacheTTL.
+func NewChecker(provider StatusProvider, thresholds Thresholds, cacheTTL time.Duration) *Checker {
+	return &Checker{
+		provider:   provider,
+		thresholds: thresholds,
+		cacheTTL:   cacheTTL,
+	}
+}
+
+// Readiness returns the faucet's current readiness, using the cached result
+// if it's still fresh.
+func (c *Checker) Readiness(ctx context.Context) *Result {
+	c.mu.Lock()
+	if c.cached != nil && time.Since(c.cachedAt) < c.cacheTTL {
+		cached := c.cached
+		c.mu.Unlock()
+		return cached
+	}
+	c.mu.Unlock()
+
+	result := c.evaluate(ctx)
+
+	c.mu.Lock()
+	c.cached = result
+	c.cachedAt = time.Now()
+	c.mu.Unlock()
+
+	return result
+}
+
+func (c *Checker) evaluate(ctx context.Context) *Result {
+	status, err := c.provider.LndStatus(ctx)
+	if err != nil || status == nil || !status.Connected {
+		info := "lnd unreachable"
+		if err != nil {
+			info = err.Error()
+		}
+		return &Result{
+			OK: false,
+			Checks: []SubCheck{
+				{Name: "lnd_connected", OK: false, Info: info},
+			},
+		}
+	}
+
+	checks := []SubCheck{
+		{Name: "lnd_connected", OK: true},
+		{Name: "synced_to_chain", OK: status.SyncedToChain},
+		{
+			Name: "wallet_balance",
+			OK:   status.ConfirmedBalanceSats >= c.thresholds.MinReadyBalanceSats,
+		},
+		{
+			Name: "pending_channels",
+			OK:   status.PendingChannels < c.thresholds.MaxPendingChannels,
+		},
+	}
+
+	ok := true
+	for _, check := range checks {
+		if !check.OK {
+			ok = false
+			break
+		}
+	}
+
+	return &Result{OK: ok, Checks: checks}
+}
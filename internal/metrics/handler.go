@@ -0,0 +1,11 @@
+package metrics
+
+import "net/http"
+
+// Handler serves r's counters in the Prometheus text exposition format.
+func Handler(r *Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		r.WriteText(w)
+	}
+}
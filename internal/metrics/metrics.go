@@ -0,0 +1,72 @@
+// Package metrics tracks faucet activity counters and renders them in the
+// Prometheus text exposition format for the /metrics endpoint.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Registry holds the faucet's counters and gauges. The zero value is ready
+// to use.
+type Registry struct {
+	channelsOpened uint64
+	channelsClosed uint64
+	invoicesPaid   uint64
+	satsDisbursed  uint64
+
+	// walletBalanceSats is updated by whichever goroutine polls lnd for
+	// the current balance (e.g. the readiness checker).
+	walletBalanceSats int64
+}
+
+// IncChannelsOpened records a successful channel open.
+func (r *Registry) IncChannelsOpened() {
+	atomic.AddUint64(&r.channelsOpened, 1)
+}
+
+// IncChannelsClosed records a channel close, cooperative or forced.
+func (r *Registry) IncChannelsClosed() {
+	atomic.AddUint64(&r.channelsClosed, 1)
+}
+
+// IncInvoicesPaid records a successfully paid invoice.
+func (r *Registry) IncInvoicesPaid() {
+	atomic.AddUint64(&r.invoicesPaid, 1)
+}
+
+// AddSatsDisbursed accumulates sats sent out via channel opens or invoice
+// payments.
+func (r *Registry) AddSatsDisbursed(sats uint64) {
+	atomic.AddUint64(&r.satsDisbursed, sats)
+}
+
+// SetWalletBalance records the faucet's current on-chain confirmed balance.
+func (r *Registry) SetWalletBalance(sats int64) {
+	atomic.StoreInt64(&r.walletBalanceSats, sats)
+}
+
+// WriteText renders the registry in the Prometheus text exposition format.
+func (r *Registry) WriteText(w io.Writer) error {
+	metrics := []struct {
+		name string
+		help string
+		typ  string
+		val  int64
+	}{
+		{"faucet_channels_opened_total", "Total number of channels opened by the faucet.", "counter", int64(atomic.LoadUint64(&r.channelsOpened))},
+		{"faucet_channels_closed_total", "Total number of channels closed by the faucet.", "counter", int64(atomic.LoadUint64(&r.channelsClosed))},
+		{"faucet_invoices_paid_total", "Total number of invoices paid by the faucet.", "counter", int64(atomic.LoadUint64(&r.invoicesPaid))},
+		{"faucet_sats_disbursed_total", "Total number of satoshis disbursed by the faucet.", "counter", int64(atomic.LoadUint64(&r.satsDisbursed))},
+		{"faucet_wallet_balance_sats", "Current on-chain confirmed wallet balance, in satoshis.", "gauge", atomic.LoadInt64(&r.walletBalanceSats)},
+	}
+
+	for _, m := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n",
+			m.name, m.help, m.name, m.typ, m.name, m.val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := &Registry{}
+	r.IncChannelsOpened()
+	r.IncChannelsOpened()
+	r.AddSatsDisbursed(1500)
+	r.SetWalletBalance(42)
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"faucet_channels_opened_total 2",
+		"faucet_sats_disbursed_total 1500",
+		"faucet_wallet_balance_sats 42",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q, got:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate describes an allowance of N events per the given duration, e.g. 1
+// event per hour or 10 events per minute.
+type Rate struct {
+	N   int
+	Per time.Duration
+}
+
+// String returns the canonical "N/unit" representation of the rate, matching
+// the format accepted by ParseRate.
+func (r Rate) String() string {
+	switch r.Per {
+	case time.Hour:
+		return fmt.Sprintf("%d/hour", r.N)
+	case time.Minute:
+		return fmt.Sprintf("%d/minute", r.N)
+	case time.Second:
+		return fmt.Sprintf("%d/second", r.N)
+	default:
+		return fmt.Sprintf("%d/%s", r.N, r.Per)
+	}
+}
+
+// ParseRate parses a rate expressed as "<count>/<unit>", where unit is one
+// of "second", "minute", or "hour". This is the format used by the
+// --rate-open-channel, --rate-pay-invoice, and --rate-generate-invoice
+// config options.
+func ParseRate(s string) (Rate, error) {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return Rate{}, fmt.Errorf("invalid rate %q, expected format "+
+			"<count>/<unit>", s)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return Rate{}, fmt.Errorf("invalid rate count %q: %v",
+			parts[0], err)
+	}
+
+	var per time.Duration
+	switch strings.ToLower(strings.TrimSpace(parts[1])) {
+	case "second", "sec", "s":
+		per = time.Second
+	case "minute", "min", "m":
+		per = time.Minute
+	case "hour", "hr", "h":
+		per = time.Hour
+	default:
+		return Rate{}, fmt.Errorf("unknown rate unit %q, want one of "+
+			"second, minute, hour", parts[1])
+	}
+
+	return Rate{N: n, Per: per}, nil
+}
+
+// bucket is a single token-bucket, keyed by some client identity and action
+// pair. It's safe for concurrent access.
+type bucket struct {
+	mu sync.Mutex
+
+	rate   Rate
+	tokens float64
+	last   time.Time
+}
+
+// newBucket creates a bucket that starts full, so the first request from a
+// new client is never unnecessarily penalized.
+func newBucket(rate Rate) *bucket {
+	return &bucket{
+		rate:   rate,
+		tokens: float64(rate.N),
+		last:   time.Now(),
+	}
+}
+
+// allow refills the bucket based on elapsed time, then attempts to withdraw a
+// single token. If the bucket is empty, it returns the duration the caller
+// should wait before retrying.
+func (b *bucket) allow(now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	elapsed := now.Sub(b.last)
+	if elapsed > 0 {
+		refill := elapsed.Seconds() * (float64(b.rate.N) / b.rate.Per.Seconds())
+		b.tokens += refill
+		if b.tokens > float64(b.rate.N) {
+			b.tokens = float64(b.rate.N)
+		}
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		missing := 1 - b.tokens
+		secsPerToken := b.rate.Per.Seconds() / float64(b.rate.N)
+		wait := time.Duration(missing * secsPerToken * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+	return true, 0
+}
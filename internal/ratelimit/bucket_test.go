@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rate
+		wantErr bool
+	}{
+		{"1/hour", Rate{N: 1, Per: time.Hour}, false},
+		{"10/minute", Rate{N: 10, Per: time.Minute}, false},
+		{"5/second", Rate{N: 5, Per: time.Second}, false},
+		{"bogus", Rate{}, true},
+		{"1/fortnight", Rate{}, true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseRate(test.in)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseRate(%q) err = %v, wantErr %v", test.in, err, test.wantErr)
+			continue
+		}
+		if err == nil && got != test.want {
+			t.Errorf("ParseRate(%q) = %+v, want %+v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestBucketAllow(t *testing.T) {
+	rate := Rate{N: 2, Per: time.Second}
+	b := newBucket(rate)
+	now := time.Now()
+
+	if ok, _ := b.allow(now); !ok {
+		t.Fatalf("first request should be allowed")
+	}
+	if ok, _ := b.allow(now); !ok {
+		t.Fatalf("second request should be allowed")
+	}
+	if ok, wait := b.allow(now); ok || wait <= 0 {
+		t.Fatalf("third request should be denied with a positive wait, got ok=%v wait=%v", ok, wait)
+	}
+
+	// After the full refill period has elapsed, the bucket should be
+	// full again.
+	if ok, _ := b.allow(now.Add(rate.Per)); !ok {
+		t.Fatalf("request after refill window should be allowed")
+	}
+}
+
+func TestStoreEviction(t *testing.T) {
+	s := NewStore(2)
+	rate := Rate{N: 1, Per: time.Minute}
+
+	s.Allow("a", rate)
+	s.Allow("b", rate)
+	if got := s.Snapshot(); got != 2 {
+		t.Fatalf("Snapshot() = %d, want 2", got)
+	}
+
+	// Adding a third key should evict the least-recently-used one ("a",
+	// since "b" was touched more recently).
+	s.Allow("c", rate)
+	if got := s.Snapshot(); got != 2 {
+		t.Fatalf("Snapshot() after eviction = %d, want 2", got)
+	}
+}
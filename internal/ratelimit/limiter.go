@@ -0,0 +1,196 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/decred/lightning-faucet/internal/auth"
+)
+
+// Config carries the per-action limits and trusted proxy list used to build
+// a Limiter.
+type Config struct {
+	// Rates maps an action name (e.g. "open-channel", "pay-invoice",
+	// "generate-invoice") to the rate permitted per client.
+	Rates map[string]Rate
+
+	// TrustedProxies is the set of CIDRs allowed to set
+	// X-Forwarded-For/X-Real-IP. Requests arriving from any other peer
+	// have their headers ignored and are keyed by RemoteAddr instead.
+	TrustedProxies []*net.IPNet
+
+	// Capacity bounds the number of distinct client buckets tracked per
+	// action before the least-recently-used one is evicted.
+	Capacity int
+}
+
+// Limiter enforces per-IP, per-action token-bucket rate limits in front of
+// the faucet's HTTP handlers.
+type Limiter struct {
+	cfg    Config
+	stores map[string]*Store
+}
+
+// New creates a Limiter from cfg. A Store is allocated per action so that a
+// client hammering one action can't starve the bucket space of another.
+func New(cfg Config) *Limiter {
+	stores := make(map[string]*Store, len(cfg.Rates))
+	for action := range cfg.Rates {
+		stores[action] = NewStore(cfg.Capacity)
+	}
+
+	return &Limiter{cfg: cfg, stores: stores}
+}
+
+// clientIP extracts the client's address, honoring X-Forwarded-For and
+// X-Real-IP only when the immediate peer is within a trusted proxy CIDR.
+func (l *Limiter) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	peer := net.ParseIP(host)
+	trusted := peer != nil && l.isTrustedProxy(peer)
+	if !trusted {
+		return host
+	}
+
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		parts := strings.Split(fwd, ",")
+		return strings.TrimSpace(parts[0])
+	}
+	if real := r.Header.Get("X-Real-IP"); real != "" {
+		return real
+	}
+
+	return host
+}
+
+func (l *Limiter) isTrustedProxy(ip net.IP) bool {
+	for _, cidr := range l.cfg.TrustedProxies {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// bucketKey returns the identity a request's rate-limit bucket should be
+// attributed to. When auth.Middleware ran ahead of this one and accepted
+// the request, that's the authenticated Subject, so the same user can't
+// reset their allowance by submitting from a different IP; otherwise it's
+// the client IP.
+func (l *Limiter) bucketKey(r *http.Request) string {
+	if id, ok := auth.FromContext(r.Context()); ok {
+		return id.Mode + ":" + id.Subject
+	}
+	return l.clientIP(r)
+}
+
+// Middleware returns an http.Handler that enforces the configured rate for
+// action before delegating to next. Requests are bucketed by the
+// authenticated identity from auth.FromContext when Middleware runs behind
+// auth.Middleware, so the same user can't reset their allowance by
+// switching IPs; it falls back to client IP otherwise. A request that
+// exceeds its allowance is rejected with a 429: API-style requests (those
+// setting Accept: application/json or hitting a JSON endpoint) get a JSON
+// body with Retry-After, while form submissions are rendered with tmpl
+// instead.
+func (l *Limiter) Middleware(action string, tmpl *template.Template) func(http.Handler) http.Handler {
+	store, ok := l.stores[action]
+	if !ok {
+		// No limit configured for this action, so don't wrap it.
+		return func(next http.Handler) http.Handler { return next }
+	}
+	rate := l.cfg.Rates[action]
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := fmt.Sprintf("%s:%s", l.bucketKey(r), action)
+			allowed, retryAfter := store.Allow(key, rate)
+			if allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			if isAPIRequest(r) || tmpl == nil {
+				json.NewEncoder(w).Encode(struct {
+					Error      string `json:"error"`
+					RetryAfter int    `json:"retry_after_secs"`
+				}{
+					Error:      fmt.Sprintf("rate limit exceeded for %s", action),
+					RetryAfter: int(retryAfter.Seconds()),
+				})
+				return
+			}
+
+			tmpl.ExecuteTemplate(w, "ratelimited.html", struct {
+				Action     string
+				RetryAfter int
+			}{
+				Action:     action,
+				RetryAfter: int(retryAfter.Seconds()),
+			})
+		})
+	}
+}
+
+// DynamicMiddleware is like Middleware, but the action to charge is decided
+// per-request by actionFor rather than fixed at wrap time. This is for
+// routes like /tools that serve more than one action (or a plain GET that
+// isn't an action at all) behind a single handler: actionFor should inspect
+// the request and return the action whose bucket applies, or "" to skip
+// rate limiting entirely.
+func (l *Limiter) DynamicMiddleware(actionFor func(*http.Request) string, tmpl *template.Template) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			action := actionFor(r)
+			if action == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			l.Middleware(action, tmpl)(next).ServeHTTP(w, r)
+		})
+	}
+}
+
+// isAPIRequest reports whether r should receive a JSON error body rather
+// than a rendered HTML page.
+func isAPIRequest(r *http.Request) bool {
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		return true
+	}
+	return strings.HasPrefix(r.URL.Path, "/tools") && r.Header.Get("X-Requested-With") != ""
+}
+
+// bucketState is the JSON shape returned by the /limits debug endpoint.
+type bucketState struct {
+	Action      string `json:"action"`
+	Rate        string `json:"rate"`
+	TrackedKeys int    `json:"tracked_keys"`
+}
+
+// LimitsHandler serves a JSON snapshot of the current rate limit
+// configuration and in-memory bucket counts, for debugging.
+func (l *Limiter) LimitsHandler(w http.ResponseWriter, r *http.Request) {
+	states := make([]bucketState, 0, len(l.stores))
+	for action, store := range l.stores {
+		states = append(states, bucketState{
+			Action:      action,
+			Rate:        l.cfg.Rates[action].String(),
+			TrackedKeys: store.Snapshot(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(states)
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/decred/lightning-faucet/internal/auth"
+)
+
+// fixedIdentityHandler is a trivial auth.AuthHandler that authenticates
+// every request as the same Identity, so auth.Middleware can be used to
+// attach an Identity to a test request the same way it would in production.
+type fixedIdentityHandler struct{ id *auth.Identity }
+
+func (h fixedIdentityHandler) Authenticate(r *http.Request) (*auth.Identity, bool, error) {
+	return h.id, true, nil
+}
+
+func TestMiddlewareChargesIdentityNotIP(t *testing.T) {
+	l := New(Config{
+		Rates:    map[string]Rate{"open-channel": {N: 1, Per: time.Hour}},
+		Capacity: 16,
+	})
+
+	var calls int
+	handler := auth.Middleware(fixedIdentityHandler{&auth.Identity{Subject: "alice", Mode: "sharedsecret"}})(
+		l.Middleware("open-channel", nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+		})))
+
+	newReq := func(remoteAddr string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = remoteAddr
+		return req
+	}
+
+	// First request from alice consumes her one-per-hour allowance...
+	handler.ServeHTTP(httptest.NewRecorder(), newReq("203.0.113.1:1111"))
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+
+	// ...and a second request from a different IP, but the same
+	// authenticated identity, should still be rejected: the bucket is
+	// keyed by Subject, not by IP, so switching addresses can't reset it.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newReq("198.51.100.9:2222"))
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1 (second request should have been rate limited)", calls)
+	}
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestMiddlewareFallsBackToIPWithoutIdentity(t *testing.T) {
+	l := New(Config{
+		Rates:    map[string]Rate{"open-channel": {N: 1, Per: time.Hour}},
+		Capacity: 16,
+	})
+
+	var calls int
+	handler := l.Middleware("open-channel", nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	req1 := httptest.NewRequest(http.MethodPost, "/", nil)
+	req1.RemoteAddr = "203.0.113.1:1111"
+	handler.ServeHTTP(httptest.NewRecorder(), req1)
+
+	req2 := httptest.NewRequest(http.MethodPost, "/", nil)
+	req2.RemoteAddr = "198.51.100.9:2222"
+	handler.ServeHTTP(httptest.NewRecorder(), req2)
+
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2 (distinct IPs with no identity should have independent buckets)", calls)
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry is the value stored in the LRU list for a given key.
+type entry struct {
+	key    string
+	bucket *bucket
+}
+
+// Store is an LRU-evicted, size-capped collection of token buckets keyed by
+// an arbitrary string (typically "<ip>:<action>"). It's the default
+// in-memory backend used by the Limiter; a Redis-backed Store can be swapped
+// in later by satisfying the same interface.
+type Store struct {
+	mu sync.Mutex
+
+	capacity int
+	ll       *list.List
+	index    map[string]*list.Element
+}
+
+// NewStore creates an in-memory Store that holds at most capacity buckets,
+// evicting the least-recently-used entry once it's full.
+func NewStore(capacity int) *Store {
+	return &Store{
+		capacity: capacity,
+		ll:       list.New(),
+		index:    make(map[string]*list.Element),
+	}
+}
+
+// Allow reports whether the event keyed by key is permitted under rate,
+// creating a fresh bucket on first use. If the event isn't permitted, the
+// returned duration is how long the caller should wait before retrying.
+func (s *Store) Allow(key string, rate Rate) (bool, time.Duration) {
+	s.mu.Lock()
+	elem, ok := s.index[key]
+	if ok {
+		s.ll.MoveToFront(elem)
+	} else {
+		elem = s.ll.PushFront(&entry{key: key, bucket: newBucket(rate)})
+		s.index[key] = elem
+		s.evictLocked()
+	}
+	b := elem.Value.(*entry).bucket
+	s.mu.Unlock()
+
+	return b.allow(time.Now())
+}
+
+// evictLocked removes the least-recently-used bucket once the store is over
+// capacity. The caller must hold s.mu.
+func (s *Store) evictLocked() {
+	if s.capacity <= 0 {
+		return
+	}
+	for s.ll.Len() > s.capacity {
+		oldest := s.ll.Back()
+		if oldest == nil {
+			return
+		}
+		s.ll.Remove(oldest)
+		delete(s.index, oldest.Value.(*entry).key)
+	}
+}
+
+// Snapshot returns the number of distinct keys currently tracked, mostly
+// useful for the /limits debug endpoint.
+func (s *Store) Snapshot() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
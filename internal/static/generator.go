@@ -0,0 +1,143 @@
+//go:build ignore
+// +build ignore
+
+// generator.go walks the source "static" and "templates" directories and
+// emits internal/static/assets_generated.go, embedding each file's raw
+// bytes plus a gzip- and zstd-precompressed variant so the /static/ handler
+// never has to compress anything at request time. Run via `go generate`
+// (see the directive atop static.go).
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	staticSrcDir    = "../../static"
+	templatesSrcDir = "../../templates"
+	outputPath      = "assets_generated.go"
+)
+
+func main() {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by go generate; DO NOT EDIT.\n\n")
+	buf.WriteString("package static\n\nfunc init() {\n")
+
+	if err := walkAndEmit(&buf, staticSrcDir, "static", true); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := walkAndEmit(&buf, templatesSrcDir, "template", false); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	buf.WriteString("}\n")
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := ioutil.WriteFile(outputPath, formatted, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// walkAndEmit walks srcDir and writes Add (and, for static assets,
+// AddEncoded) calls for every file found under it.
+func walkAndEmit(buf *bytes.Buffer, srcDir, filetype string, precompress bool) error {
+	if _, err := os.Stat(srcDir); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		assetPath := "/" + filepath.ToSlash(rel)
+
+		fmt.Fprintf(buf, "\tAdd(%q, %q, %s)\n", filetype, assetPath, byteLiteral(content))
+
+		if !precompress {
+			return nil
+		}
+
+		gz, err := gzipBytes(content)
+		if err != nil {
+			return err
+		}
+		zst, err := zstdBytes(content)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(buf, "\tAddEncoded(%q, \"gzip\", %s)\n", assetPath, byteLiteral(gz))
+		fmt.Fprintf(buf, "\tAddEncoded(%q, \"zstd\", %s)\n", assetPath, byteLiteral(zst))
+		return nil
+	})
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func zstdBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := zstd.NewWriter(&buf, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// byteLiteral renders data as a Go []byte composite literal.
+func byteLiteral(data []byte) string {
+	var sb strings.Builder
+	sb.WriteString("[]byte{")
+	for i, b := range data {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "0x%02x", b)
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
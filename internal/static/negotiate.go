@@ -0,0 +1,57 @@
+package static
+
+import (
+	"strconv"
+	"strings"
+)
+
+// preferredEncodings lists the encodings BestEncoding will choose between,
+// in priority order.
+var preferredEncodings = []string{"zstd", "gzip"}
+
+// BestEncoding picks the best encoding for a request's Accept-Encoding
+// header out of the encodings available for a given asset, preferring zstd
+// over gzip over the uncompressed original. It returns "" for the
+// uncompressed original. An encoding with an explicit q=0 (e.g.
+// "gzip;q=0") is treated as rejected, per RFC 7231 §5.3.4, even though its
+// token is present in the header.
+func BestEncoding(acceptEncoding string, available func(encoding string) bool) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, q := parseEncodingParam(part)
+		if name == "" {
+			continue
+		}
+		accepted[name] = q > 0
+	}
+
+	for _, encoding := range preferredEncodings {
+		if accepted[encoding] && available(encoding) {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// parseEncodingParam splits a single Accept-Encoding list element such as
+// "gzip;q=0.5" into its token and q value. A malformed or missing q
+// defaults to 1 (fully accepted).
+func parseEncodingParam(part string) (name string, q float64) {
+	fields := strings.SplitN(part, ";", 2)
+	name = strings.TrimSpace(fields[0])
+	if name == "" {
+		return "", 0
+	}
+
+	q = 1
+	if len(fields) == 2 {
+		param := strings.TrimSpace(fields[1])
+		if strings.HasPrefix(param, "q=") {
+			v := strings.TrimSpace(strings.TrimPrefix(param, "q="))
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+	}
+	return name, q
+}
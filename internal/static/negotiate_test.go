@@ -0,0 +1,38 @@
+package static
+
+import "testing"
+
+func TestBestEncoding(t *testing.T) {
+	has := func(encodings ...string) func(string) bool {
+		set := make(map[string]bool)
+		for _, e := range encodings {
+			set[e] = true
+		}
+		return func(e string) bool { return set[e] }
+	}
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		available      func(string) bool
+		want           string
+	}{
+		{"prefers zstd over gzip", "gzip, zstd, br", has("gzip", "zstd"), "zstd"},
+		{"falls back to gzip", "gzip, deflate", has("gzip", "zstd"), "gzip"},
+		{"no overlap returns identity", "br", has("gzip", "zstd"), ""},
+		{"empty header returns identity", "", has("gzip", "zstd"), ""},
+		{"accepted but not generated", "zstd", has("gzip"), ""},
+		{"explicit q=0 rejects gzip", "gzip;q=0, zstd", has("gzip", "zstd"), "zstd"},
+		{"explicit q=0 with no alternative returns identity", "gzip;q=0", has("gzip", "zstd"), ""},
+		{"q=0 on unused encoding doesn't affect others", "gzip;q=0.5, zstd;q=0", has("gzip", "zstd"), "gzip"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := BestEncoding(test.acceptEncoding, test.available)
+			if got != test.want {
+				t.Errorf("BestEncoding(%q) = %q, want %q", test.acceptEncoding, got, test.want)
+			}
+		})
+	}
+}
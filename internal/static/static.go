@@ -4,6 +4,11 @@ package static
 
 type storage struct {
 	assets, templates map[string][]byte
+
+	// encoded holds precompressed variants of each asset, keyed by
+	// filepath then by encoding ("gzip", "zstd"). Populated at generate
+	// time so serving a compressed variant costs zero runtime CPU.
+	encoded map[string]map[string][]byte
 }
 
 // newEmbedFiles creates a new storage for embed files.
@@ -11,6 +16,7 @@ func newStorage() *storage {
 	return &storage{
 		assets:    make(map[string][]byte),
 		templates: make(map[string][]byte),
+		encoded:   make(map[string]map[string][]byte),
 	}
 }
 
@@ -23,6 +29,15 @@ func (s *storage) Add(filetype string, filepath string, content []byte) {
 	}
 }
 
+// AddEncoded registers a precompressed variant of the static asset at
+// filepath under encoding (e.g. "gzip" or "zstd").
+func (s *storage) AddEncoded(filepath string, encoding string, content []byte) {
+	if s.encoded[filepath] == nil {
+		s.encoded[filepath] = make(map[string][]byte)
+	}
+	s.encoded[filepath][encoding] = content
+}
+
 // Assets returns the assets map.
 func (s *storage) Assets() map[string][]byte {
 	return s.assets
@@ -33,6 +48,13 @@ func (s *storage) Templates() map[string][]byte {
 	return s.templates
 }
 
+// Encoded returns the precompressed variant of filepath stored under
+// encoding, if one was generated.
+func (s *storage) Encoded(filepath string, encoding string) ([]byte, bool) {
+	content, ok := s.encoded[filepath][encoding]
+	return content, ok
+}
+
 // Expose the embed files.
 var s = newStorage()
 
@@ -41,6 +63,12 @@ func Add(filetype string, filepath string, content []byte) {
 	s.Add(filetype, filepath, content)
 }
 
+// AddEncoded registers a precompressed variant of the static asset at
+// filepath under encoding (e.g. "gzip" or "zstd").
+func AddEncoded(filepath string, encoding string, content []byte) {
+	s.AddEncoded(filepath, encoding, content)
+}
+
 // Assets returns the assets map.
 func Assets() map[string][]byte {
 	return s.Assets()
@@ -50,3 +78,9 @@ func Assets() map[string][]byte {
 func Templates() map[string][]byte {
 	return s.Templates()
 }
+
+// Encoded returns the precompressed variant of filepath stored under
+// encoding, if one was generated.
+func Encoded(filepath string, encoding string) ([]byte, bool) {
+	return s.Encoded(filepath, encoding)
+}
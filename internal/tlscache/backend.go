@@ -0,0 +1,27 @@
+package tlscache
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned by a Backend when the requested key has never
+// been written. It's distinct from autocert.ErrCacheMiss so that Cache can
+// translate it per-role: a leader treats it as a cache miss (and goes on to
+// solve an ACME challenge), a follower treats it as errCertUnavailable.
+var ErrNotFound = errors.New("tlscache: key not found")
+
+// Backend is the storage interface shared by the filesystem, S3-compatible,
+// and Postgres implementations. All three can live behind the same Cache,
+// selected via --acme-cache-backend.
+type Backend interface {
+	// Get returns the stored bytes for key, or ErrNotFound if key has
+	// never been written.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Put stores data under key, overwriting any previous value.
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Delete removes key. It's not an error if key doesn't exist.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,105 @@
+// Package tlscache implements an HA-safe autocert.Cache that lets one
+// "leader" replica solve ACME challenges and write new certs to shared
+// storage, while "follower" replicas serve certs read-only and fail fast
+// instead of racing the leader for a new order.
+package tlscache
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// errCertUnavailable is returned by a follower Cache on a miss. It is
+// deliberately distinct from autocert.ErrCacheMiss: returning ErrCacheMiss
+// would cause autocert.Manager to attempt its own ACME order, which is
+// exactly what followers must not do.
+var errCertUnavailable = errors.New("tlscache: certificate not available on this replica")
+
+// Role selects how a Cache behaves on a miss and on writes.
+type Role string
+
+const (
+	// RoleLeader solves ACME challenges: a miss is reported as
+	// autocert.ErrCacheMiss so the manager issues a new cert, and
+	// successful issuance is written through to the shared Backend.
+	RoleLeader Role = "leader"
+
+	// RoleFollower never issues certs: a miss is reported as
+	// errCertUnavailable so GetCertificate fails fast, and writes are
+	// rejected outright.
+	RoleFollower Role = "follower"
+)
+
+// Cache adapts a Backend into an autocert.Cache, behaving differently on a
+// miss depending on its current role.
+type Cache struct {
+	backend Backend
+	role    func() Role
+
+	// snapshot is a follower-only in-memory mirror of the backend,
+	// populated by StartFollowerRefresher so a TLS handshake doesn't
+	// have to round-trip to shared storage. It stays nil (and unused)
+	// on a leader.
+	mu       sync.RWMutex
+	snapshot map[string][]byte
+}
+
+// New creates a Cache backed by backend, fixed at role for its lifetime.
+// Use NewDynamic instead for --acme-role=auto, where the role can change at
+// runtime as leadership is won or lost.
+func New(backend Backend, role Role) *Cache {
+	return &Cache{backend: backend, role: func() Role { return role }}
+}
+
+// NewDynamic creates a Cache whose role is read from auto on every call, for
+// --acme-role=auto where RunElection flips leadership at runtime.
+func NewDynamic(backend Backend, auto *AutoRole) *Cache {
+	return &Cache{backend: backend, role: auto.Role}
+}
+
+// Get implements autocert.Cache.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	role := c.role()
+
+	if role == RoleFollower {
+		c.mu.RLock()
+		data, ok := c.snapshot[key]
+		c.mu.RUnlock()
+		if ok {
+			return data, nil
+		}
+	}
+
+	data, err := c.backend.Get(ctx, key)
+	switch {
+	case err == nil:
+		return data, nil
+	case err == ErrNotFound && role == RoleLeader:
+		return nil, autocert.ErrCacheMiss
+	case err == ErrNotFound:
+		return nil, errCertUnavailable
+	default:
+		return nil, err
+	}
+}
+
+// Put implements autocert.Cache. Followers never issue certs, so a Put
+// against a follower Cache is a bug in the caller rather than a normal
+// miss, and is rejected.
+func (c *Cache) Put(ctx context.Context, key string, data []byte) error {
+	if c.role() == RoleFollower {
+		return errCertUnavailable
+	}
+	return c.backend.Put(ctx, key, data)
+}
+
+// Delete implements autocert.Cache.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	if c.role() == RoleFollower {
+		return errCertUnavailable
+	}
+	return c.backend.Delete(ctx, key)
+}
@@ -0,0 +1,74 @@
+package tlscache
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+type memBackend struct {
+	data map[string][]byte
+}
+
+func newMemBackend() *memBackend {
+	return &memBackend{data: make(map[string][]byte)}
+}
+
+func (m *memBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	data, ok := m.data[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+func (m *memBackend) Put(ctx context.Context, key string, data []byte) error {
+	m.data[key] = data
+	return nil
+}
+
+func (m *memBackend) Delete(ctx context.Context, key string) error {
+	delete(m.data, key)
+	return nil
+}
+
+func TestLeaderCacheMissReportsAutocertMiss(t *testing.T) {
+	c := New(newMemBackend(), RoleLeader)
+
+	_, err := c.Get(context.Background(), "example.com")
+	if err != autocert.ErrCacheMiss {
+		t.Fatalf("Get() error = %v, want autocert.ErrCacheMiss", err)
+	}
+}
+
+func TestFollowerCacheMissFailsFastWithoutAutocertMiss(t *testing.T) {
+	c := New(newMemBackend(), RoleFollower)
+
+	_, err := c.Get(context.Background(), "example.com")
+	if err == nil || err == autocert.ErrCacheMiss {
+		t.Fatalf("Get() error = %v, want a non-nil error distinct from autocert.ErrCacheMiss", err)
+	}
+}
+
+func TestFollowerPutRejected(t *testing.T) {
+	c := New(newMemBackend(), RoleFollower)
+
+	if err := c.Put(context.Background(), "example.com", []byte("cert")); err == nil {
+		t.Fatalf("Put() on a follower should be rejected")
+	}
+}
+
+func TestLeaderWriteThenGet(t *testing.T) {
+	c := New(newMemBackend(), RoleLeader)
+	ctx := context.Background()
+
+	if err := c.Put(ctx, "example.com", []byte("cert")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	data, err := c.Get(ctx, "example.com")
+	if err != nil || string(data) != "cert" {
+		t.Fatalf("Get() = %q, %v, want \"cert\", nil", data, err)
+	}
+}
@@ -0,0 +1,142 @@
+package tlscache
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Elector decides which replica holds the leader role when
+// --acme-role=auto. Implementations should be safe to poll repeatedly: the
+// leader must periodically renew its lease, and a follower must notice
+// when the leader disappears.
+type Elector interface {
+	// TryAcquire attempts to (re-)claim leadership for leaseTTL. It
+	// returns true if this replica is (or remains) the leader.
+	TryAcquire(ctx context.Context, leaseTTL time.Duration) (bool, error)
+}
+
+// NewHolderID generates a random identifier a replica can pass to
+// NewFileLockElector/NewDBLockElector to distinguish "I am the incumbent
+// leader, renewing" from "another replica holds the lease".
+func NewHolderID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// FileLockElector implements a simple file-based lease: the lock file's
+// mtime is the lease expiry, and any replica may steal an expired lease.
+// The file's contents record the current holder's ID, so the incumbent can
+// tell its own unexpired lease apart from another replica's and renew it
+// instead of being told the lease is held. This is adequate for a single
+// shared filesystem volume; the Postgres-row variant should be preferred
+// when replicas don't share a filesystem.
+type FileLockElector struct {
+	Path     string
+	HolderID string
+}
+
+// NewFileLockElector creates an Elector backed by a lock file at path.
+// holderID must be unique per replica (see NewHolderID).
+func NewFileLockElector(path, holderID string) *FileLockElector {
+	return &FileLockElector{Path: path, HolderID: holderID}
+}
+
+// TryAcquire implements Elector. The read-check-write sequence is
+// serialized across replicas by exclusively creating a sibling ".lock"
+// file first: os.O_EXCL makes that create atomic, so two replicas racing
+// right at expiry can't both observe "expired" and both claim leadership.
+func (e *FileLockElector) TryAcquire(ctx context.Context, leaseTTL time.Duration) (bool, error) {
+	mutex, err := os.OpenFile(e.Path+".lock", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			// Another replica is mid-claim; try again next poll.
+			return false, nil
+		}
+		return false, err
+	}
+	defer func() {
+		mutex.Close()
+		os.Remove(e.Path + ".lock")
+	}()
+
+	content, err := os.ReadFile(e.Path)
+	switch {
+	case err == nil:
+		info, statErr := os.Stat(e.Path)
+		if statErr != nil {
+			return false, statErr
+		}
+		holder := strings.TrimSpace(string(content))
+		if holder != e.HolderID && time.Since(info.ModTime()) < leaseTTL {
+			// Someone else holds an unexpired lease.
+			return false, nil
+		}
+	case os.IsNotExist(err):
+		// No lease exists yet; fall through and claim it.
+	default:
+		return false, err
+	}
+
+	// The lease is missing, expired, or already ours: claim/renew it by
+	// rewriting the file's contents and mtime.
+	if err := os.WriteFile(e.Path, []byte(e.HolderID+"\n"), 0600); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DBLockElector implements a lease using a single row in a Postgres (or
+// any database/sql-compatible) table with the schema:
+//
+//	CREATE TABLE IF NOT EXISTS tlscache_leader (
+//		id         TEXT PRIMARY KEY,
+//		holder_id  TEXT NOT NULL,
+//		expires_at TIMESTAMPTZ NOT NULL
+//	);
+type DBLockElector struct {
+	DB       *sql.DB
+	Table    string
+	RowID    string
+	HolderID string
+}
+
+// NewDBLockElector creates an Elector backed by a row in table, identified
+// by rowID (so multiple faucet deployments can share one table). holderID
+// must be unique per replica (see NewHolderID).
+func NewDBLockElector(db *sql.DB, table, rowID, holderID string) *DBLockElector {
+	return &DBLockElector{DB: db, Table: table, RowID: rowID, HolderID: holderID}
+}
+
+// TryAcquire implements Elector. The UPDATE fires either when the existing
+// lease has expired or when it's already held by this replica, so the
+// incumbent leader can renew its own row every cycle instead of being
+// rejected by its own still-valid lease.
+func (e *DBLockElector) TryAcquire(ctx context.Context, leaseTTL time.Duration) (bool, error) {
+	expiresAt := time.Now().Add(leaseTTL)
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, holder_id, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET holder_id = $2, expires_at = $3
+		WHERE %s.expires_at < now() OR %s.holder_id = $2
+	`, e.Table, e.Table, e.Table)
+
+	res, err := e.DB.ExecContext(ctx, query, e.RowID, e.HolderID, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
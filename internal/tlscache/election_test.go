@@ -0,0 +1,87 @@
+package tlscache
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileLockElectorIncumbentRenewsOwnLease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	leaseTTL := 50 * time.Millisecond
+
+	incumbent := NewFileLockElector(path, "replica-a")
+
+	ok, err := incumbent.TryAcquire(context.Background(), leaseTTL)
+	if err != nil {
+		t.Fatalf("initial TryAcquire() error = %v", err)
+	}
+	if !ok {
+		t.Fatalf("initial TryAcquire() = false, want true")
+	}
+
+	// The incumbent renews well before its own lease expires. Previously
+	// this was rejected (time.Since(mtime) < leaseTTL), demoting a
+	// healthy leader on a 100%-reproducible schedule.
+	ok, err = incumbent.TryAcquire(context.Background(), leaseTTL)
+	if err != nil {
+		t.Fatalf("renewal TryAcquire() error = %v", err)
+	}
+	if !ok {
+		t.Fatalf("renewal TryAcquire() = false, want true (incumbent should renew its own unexpired lease)")
+	}
+}
+
+func TestFileLockElectorRejectsOtherHoldersUnexpiredLease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	leaseTTL := time.Hour
+
+	a := NewFileLockElector(path, "replica-a")
+	b := NewFileLockElector(path, "replica-b")
+
+	ok, err := a.TryAcquire(context.Background(), leaseTTL)
+	if err != nil || !ok {
+		t.Fatalf("a.TryAcquire() = %v, %v, want true, nil", ok, err)
+	}
+
+	ok, err = b.TryAcquire(context.Background(), leaseTTL)
+	if err != nil {
+		t.Fatalf("b.TryAcquire() error = %v", err)
+	}
+	if ok {
+		t.Fatalf("b.TryAcquire() = true, want false (a's lease hasn't expired)")
+	}
+}
+
+func TestFileLockElectorOtherReplicaClaimsExpiredLease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	leaseTTL := 10 * time.Millisecond
+
+	a := NewFileLockElector(path, "replica-a")
+	b := NewFileLockElector(path, "replica-b")
+
+	ok, err := a.TryAcquire(context.Background(), leaseTTL)
+	if err != nil || !ok {
+		t.Fatalf("a.TryAcquire() = %v, %v, want true, nil", ok, err)
+	}
+
+	time.Sleep(2 * leaseTTL)
+
+	ok, err = b.TryAcquire(context.Background(), leaseTTL)
+	if err != nil {
+		t.Fatalf("b.TryAcquire() error = %v", err)
+	}
+	if !ok {
+		t.Fatalf("b.TryAcquire() = false, want true (a's lease has expired)")
+	}
+
+	// a should now see b's unexpired lease and lose leadership.
+	ok, err = a.TryAcquire(context.Background(), leaseTTL)
+	if err != nil {
+		t.Fatalf("a.TryAcquire() (post-steal) error = %v", err)
+	}
+	if ok {
+		t.Fatalf("a.TryAcquire() (post-steal) = true, want false")
+	}
+}
@@ -0,0 +1,50 @@
+package tlscache
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FilesystemBackend stores cert bytes as files under Dir, which is expected
+// to be a volume shared by every replica (e.g. an NFS mount).
+type FilesystemBackend struct {
+	Dir string
+}
+
+// NewFilesystemBackend creates a Backend rooted at dir, creating it if it
+// doesn't already exist.
+func NewFilesystemBackend(dir string) (*FilesystemBackend, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FilesystemBackend{Dir: dir}, nil
+}
+
+func (f *FilesystemBackend) path(key string) string {
+	return filepath.Join(f.Dir, filepath.Base(key))
+}
+
+// Get implements Backend.
+func (f *FilesystemBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := ioutil.ReadFile(f.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Put implements Backend.
+func (f *FilesystemBackend) Put(ctx context.Context, key string, data []byte) error {
+	return ioutil.WriteFile(f.path(key), data, 0600)
+}
+
+// Delete implements Backend.
+func (f *FilesystemBackend) Delete(ctx context.Context, key string) error {
+	err := os.Remove(f.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
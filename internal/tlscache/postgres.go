@@ -0,0 +1,49 @@
+package tlscache
+
+import (
+	"context"
+	"database/sql"
+)
+
+// PostgresBackend stores cert bytes as rows in a table with the schema:
+//
+//	CREATE TABLE IF NOT EXISTS tlscache (
+//		key   TEXT PRIMARY KEY,
+//		value BYTEA NOT NULL
+//	);
+type PostgresBackend struct {
+	DB    *sql.DB
+	Table string
+}
+
+// NewPostgresBackend creates a Backend backed by table in db. Callers are
+// expected to have already created the table (see the schema above).
+func NewPostgresBackend(db *sql.DB, table string) *PostgresBackend {
+	return &PostgresBackend{DB: db, Table: table}
+}
+
+// Get implements Backend.
+func (p *PostgresBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	query := "SELECT value FROM " + p.Table + " WHERE key = $1"
+	err := p.DB.QueryRowContext(ctx, query, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Put implements Backend.
+func (p *PostgresBackend) Put(ctx context.Context, key string, data []byte) error {
+	query := "INSERT INTO " + p.Table + " (key, value) VALUES ($1, $2) " +
+		"ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value"
+	_, err := p.DB.ExecContext(ctx, query, key, data)
+	return err
+}
+
+// Delete implements Backend.
+func (p *PostgresBackend) Delete(ctx context.Context, key string) error {
+	query := "DELETE FROM " + p.Table + " WHERE key = $1"
+	_, err := p.DB.ExecContext(ctx, query, key)
+	return err
+}
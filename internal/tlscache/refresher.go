@@ -0,0 +1,58 @@
+package tlscache
+
+import (
+	"context"
+	"time"
+)
+
+// StartFollowerRefresher launches a background goroutine that polls the
+// backend for each of keys every interval, keeping cache's in-memory
+// snapshot warm so newly issued certs propagate to followers without a
+// per-handshake round trip to shared storage. It re-checks cache's role on
+// every tick rather than once at startup, so a replica running under
+// --acme-role=auto keeps refreshing correctly across leader/follower
+// transitions instead of being permanently disabled if it happened to start
+// as leader. It's simply a no-op while the role is leader. The returned
+// func stops the poller.
+func StartFollowerRefresher(cache *Cache, keys []string, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		refresh := func() {
+			if cache.role() != RoleFollower {
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			defer cancel()
+
+			for _, key := range keys {
+				data, err := cache.backend.Get(ctx, key)
+				if err != nil {
+					continue
+				}
+
+				cache.mu.Lock()
+				if cache.snapshot == nil {
+					cache.snapshot = make(map[string][]byte)
+				}
+				cache.snapshot[key] = data
+				cache.mu.Unlock()
+			}
+		}
+
+		refresh()
+		for {
+			select {
+			case <-ticker.C:
+				refresh()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
@@ -0,0 +1,50 @@
+package tlscache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartFollowerRefresherPicksUpLateRoleTransition(t *testing.T) {
+	backend := newMemBackend()
+	backend.data["example.com"] = []byte("cert-v1")
+
+	auto := &AutoRole{}
+	auto.current.Store(RoleLeader)
+	cache := NewDynamic(backend, auto)
+
+	stop := StartFollowerRefresher(cache, []string{"example.com"}, 10*time.Millisecond)
+	defer stop()
+
+	// While this replica is leader, the refresher should not populate
+	// the follower-only snapshot.
+	time.Sleep(30 * time.Millisecond)
+	cache.mu.RLock()
+	_, warmed := cache.snapshot["example.com"]
+	cache.mu.RUnlock()
+	if warmed {
+		t.Fatalf("snapshot was warmed while role was leader")
+	}
+
+	// Demote to follower; the still-running refresher should notice on
+	// its next tick without needing to be restarted.
+	auto.current.Store(RoleFollower)
+
+	deadline := time.After(500 * time.Millisecond)
+	for {
+		cache.mu.RLock()
+		data, ok := cache.snapshot["example.com"]
+		cache.mu.RUnlock()
+		if ok {
+			if string(data) != "cert-v1" {
+				t.Fatalf("snapshot = %q, want \"cert-v1\"", data)
+			}
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("refresher never warmed the snapshot after demotion to follower")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
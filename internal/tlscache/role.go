@@ -0,0 +1,78 @@
+package tlscache
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ParseRole validates a --acme-role value. "auto" isn't a Role itself; it's
+// resolved into RoleLeader or RoleFollower by RunElection.
+func ParseRole(s string) (Role, error) {
+	switch Role(s) {
+	case RoleLeader, RoleFollower:
+		return Role(s), nil
+	default:
+		return "", fmt.Errorf("unknown acme role %q, want one of "+
+			"leader, follower, auto", s)
+	}
+}
+
+// AutoRole holds the result of continuous leader election for
+// --acme-role=auto, safe to read from multiple goroutines.
+type AutoRole struct {
+	current atomic.Value // Role
+}
+
+// Role returns the most recently resolved role.
+func (a *AutoRole) Role() Role {
+	v := a.current.Load()
+	if v == nil {
+		return RoleFollower
+	}
+	return v.(Role)
+}
+
+// RunElection polls elector every leaseTTL/2 to acquire or renew
+// leadership, updating AutoRole's current value. It runs until stop is
+// closed.
+func RunElection(elector Elector, leaseTTL time.Duration, stop <-chan struct{}) *AutoRole {
+	auto := &AutoRole{}
+	auto.current.Store(RoleFollower)
+
+	poll := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), leaseTTL/2)
+		defer cancel()
+
+		isLeader, err := elector.TryAcquire(ctx, leaseTTL)
+		if err != nil {
+			// Leave the last known role in place; a transient
+			// storage error shouldn't flip a healthy leader to
+			// follower.
+			return
+		}
+		if isLeader {
+			auto.current.Store(RoleLeader)
+		} else {
+			auto.current.Store(RoleFollower)
+		}
+	}
+
+	poll()
+	go func() {
+		ticker := time.NewTicker(leaseTTL / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				poll()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return auto
+}
@@ -0,0 +1,65 @@
+package tlscache
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+)
+
+// S3Client is the minimal subset of an S3-compatible client that
+// S3Backend needs, so this package doesn't pull in a particular SDK. Any
+// client wrapping PutObject/GetObject/DeleteObject against a bucket can
+// satisfy it.
+//
+// GetObject MUST return ErrNotFound (not the SDK's own NoSuchKey/NotFound
+// error) when key doesn't exist. S3Backend.Get relies on this to tell a
+// genuine cache miss apart from a transport or permissions error, so an
+// adapter over a real SDK needs to translate its not-found error into
+// ErrNotFound before returning.
+type S3Client interface {
+	PutObject(ctx context.Context, bucket, key string, body []byte) error
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+// S3Backend stores cert bytes as objects in an S3-compatible bucket, so
+// replicas running in different availability zones (or different clouds
+// entirely) can still share one cache.
+type S3Backend struct {
+	Client S3Client
+	Bucket string
+	Prefix string
+}
+
+// NewS3Backend creates a Backend that stores objects in bucket under
+// prefix, using client for the underlying API calls.
+func NewS3Backend(client S3Client, bucket, prefix string) *S3Backend {
+	return &S3Backend{Client: client, Bucket: bucket, Prefix: prefix}
+}
+
+func (s *S3Backend) objectKey(key string) string {
+	return s.Prefix + key
+}
+
+// Get implements Backend.
+func (s *S3Backend) Get(ctx context.Context, key string) ([]byte, error) {
+	body, err := s.Client.GetObject(ctx, s.Bucket, s.objectKey(key))
+	if err == ErrNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
+
+// Put implements Backend.
+func (s *S3Backend) Put(ctx context.Context, key string, data []byte) error {
+	return s.Client.PutObject(ctx, s.Bucket, s.objectKey(key), data)
+}
+
+// Delete implements Backend.
+func (s *S3Backend) Delete(ctx context.Context, key string) error {
+	return s.Client.DeleteObject(ctx, s.Bucket, s.objectKey(key))
+}
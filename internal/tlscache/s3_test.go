@@ -0,0 +1,71 @@
+package tlscache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// fakeS3Client is an in-memory stand-in for a real S3 SDK client. Per the
+// S3Client contract, GetObject returns ErrNotFound on a miss.
+type fakeS3Client struct {
+	objects map[string][]byte
+}
+
+func newFakeS3Client() *fakeS3Client {
+	return &fakeS3Client{objects: make(map[string][]byte)}
+}
+
+func (f *fakeS3Client) PutObject(ctx context.Context, bucket, key string, body []byte) error {
+	f.objects[bucket+"/"+key] = body
+	return nil
+}
+
+func (f *fakeS3Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	data, ok := f.objects[bucket+"/"+key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (f *fakeS3Client) DeleteObject(ctx context.Context, bucket, key string) error {
+	delete(f.objects, bucket+"/"+key)
+	return nil
+}
+
+func TestS3BackendGetMiss(t *testing.T) {
+	backend := NewS3Backend(newFakeS3Client(), "certs", "acme/")
+
+	_, err := backend.Get(context.Background(), "example.com")
+	if err != ErrNotFound {
+		t.Fatalf("Get() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestS3BackendPutThenGet(t *testing.T) {
+	backend := NewS3Backend(newFakeS3Client(), "certs", "acme/")
+	ctx := context.Background()
+
+	if err := backend.Put(ctx, "example.com", []byte("cert-bytes")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	data, err := backend.Get(ctx, "example.com")
+	if err != nil || string(data) != "cert-bytes" {
+		t.Fatalf("Get() = %q, %v, want \"cert-bytes\", nil", data, err)
+	}
+}
+
+func TestS3BackendLeaderMissReportsAutocertMiss(t *testing.T) {
+	backend := NewS3Backend(newFakeS3Client(), "certs", "acme/")
+	cache := New(backend, RoleLeader)
+
+	if _, err := cache.Get(context.Background(), "example.com"); err != autocert.ErrCacheMiss {
+		t.Fatalf("Get() error = %v, want autocert.ErrCacheMiss", err)
+	}
+}
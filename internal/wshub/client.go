@@ -0,0 +1,80 @@
+package wshub
+
+import (
+	"github.com/gorilla/websocket"
+)
+
+// clientBacklog bounds how many unsent events we'll queue for a single slow
+// client before dropping the oldest one. This keeps one stuck browser tab
+// from growing without bound or stalling the broadcaster.
+const clientBacklog = 32
+
+// client represents a single connected browser. Events destined for it are
+// queued on send and flushed by writePump; if the queue is full the oldest
+// queued event is discarded to make room for the new one.
+type client struct {
+	hub       *Hub
+	conn      *websocket.Conn
+	requestID string
+
+	send chan Event
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, requestID string) *client {
+	return &client{
+		hub:       hub,
+		conn:      conn,
+		requestID: requestID,
+		send:      make(chan Event, clientBacklog),
+	}
+}
+
+// enqueue pushes ev onto the client's send queue, dropping the oldest queued
+// event if the queue is already full.
+func (c *client) enqueue(ev Event) {
+	select {
+	case c.send <- ev:
+		return
+	default:
+	}
+
+	// The queue is full: drop the oldest event and retry once. Another
+	// goroutine may race us for the dropped slot, so fall back to a
+	// non-blocking send either way rather than blocking the broadcaster.
+	select {
+	case <-c.send:
+	default:
+	}
+	select {
+	case c.send <- ev:
+	default:
+	}
+}
+
+// writePump drains the client's send queue onto the underlying websocket
+// connection until it's closed. It must run in its own goroutine.
+func (c *client) writePump() {
+	defer c.conn.Close()
+
+	for ev := range c.send {
+		if err := c.conn.WriteJSON(ev); err != nil {
+			return
+		}
+	}
+}
+
+// readPump discards incoming messages (the faucet's client doesn't send
+// anything meaningful over /ws) but is required to process control frames
+// and to notice when the peer goes away.
+func (c *client) readPump() {
+	defer func() {
+		c.hub.unregister <- c
+		c.conn.Close()
+	}()
+
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package wshub
+
+import "testing"
+
+func TestClientEnqueueDropsOldestWhenFull(t *testing.T) {
+	c := &client{send: make(chan Event, 2)}
+
+	c.enqueue(Event{Type: "one"})
+	c.enqueue(Event{Type: "two"})
+	c.enqueue(Event{Type: "three"})
+
+	// The queue only holds 2, so "one" should have been dropped in favor
+	// of "three".
+	first := <-c.send
+	second := <-c.send
+
+	if first.Type != "two" || second.Type != "three" {
+		t.Fatalf("got events %q, %q; want \"two\", \"three\"", first.Type, second.Type)
+	}
+}
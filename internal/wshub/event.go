@@ -0,0 +1,20 @@
+package wshub
+
+// Event is a single message fanned out to connected browsers over /ws. It
+// mirrors one of the lnd subscription streams the faucet consumes
+// (SubscribeChannelEvents, SubscribeInvoices, SubscribeTransactions) or the
+// progress of an in-flight SendPayment call.
+type Event struct {
+	// RequestID ties this event back to the pending request ID returned
+	// from the POST handler that kicked off the channel open or invoice
+	// payment, so the browser that submitted the form can match it to
+	// the right in-page status indicator.
+	RequestID string `json:"request_id"`
+
+	// Type identifies the kind of event, e.g. "channel_pending",
+	// "channel_active", "invoice_settled", or "payment_progress".
+	Type string `json:"type"`
+
+	// Data carries the event-specific payload and is serialized as-is.
+	Data interface{} `json:"data"`
+}
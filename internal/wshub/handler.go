@@ -0,0 +1,33 @@
+package wshub
+
+import (
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// upgrader is shared across all /ws connections. Origin checking is left to
+// the default (same-origin) policy since the faucet serves its own JS
+// client.
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// ServeWS upgrades r to a websocket connection and registers it with hub
+// under the "request_id" query parameter, so subsequent Publish calls tagged
+// with that ID are delivered to this browser. An empty request_id is valid
+// and receives only events published with an empty RequestID.
+func ServeWS(hub *Hub, w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	requestID := r.URL.Query().Get("request_id")
+	c := newClient(hub, conn, requestID)
+	hub.register <- c
+
+	go c.writePump()
+	go c.readPump()
+}
@@ -0,0 +1,61 @@
+package wshub
+
+// Hub fans out Events to the browsers connected over /ws, keyed by the
+// pending request ID each browser registered with. It runs its own event
+// loop so that registration, unregistration, and publishing never race.
+type Hub struct {
+	clients    map[string]map[*client]struct{}
+	register   chan *client
+	unregister chan *client
+	publish    chan Event
+}
+
+// NewHub creates a Hub. Callers must run Hub.Run in its own goroutine before
+// any events will be delivered.
+func NewHub() *Hub {
+	return &Hub{
+		clients:    make(map[string]map[*client]struct{}),
+		register:   make(chan *client),
+		unregister: make(chan *client),
+		publish:    make(chan Event),
+	}
+}
+
+// Run processes registrations and publishes until stop is closed.
+func (h *Hub) Run(stop <-chan struct{}) {
+	for {
+		select {
+		case c := <-h.register:
+			if h.clients[c.requestID] == nil {
+				h.clients[c.requestID] = make(map[*client]struct{})
+			}
+			h.clients[c.requestID][c] = struct{}{}
+
+		case c := <-h.unregister:
+			if peers, ok := h.clients[c.requestID]; ok {
+				if _, ok := peers[c]; ok {
+					delete(peers, c)
+					close(c.send)
+					if len(peers) == 0 {
+						delete(h.clients, c.requestID)
+					}
+				}
+			}
+
+		case ev := <-h.publish:
+			for c := range h.clients[ev.RequestID] {
+				c.enqueue(ev)
+			}
+
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Publish fans ev out to every client registered under ev.RequestID. It's
+// safe to call from any goroutine, in particular the lnd subscription
+// readers.
+func (h *Hub) Publish(ev Event) {
+	h.publish <- ev
+}
@@ -3,18 +3,27 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/sha1"
 	"crypto/tls"
 	"fmt"
 	"html/template"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/decred/lightning-faucet/internal/auth"
+	"github.com/decred/lightning-faucet/internal/health"
+	"github.com/decred/lightning-faucet/internal/metrics"
+	"github.com/decred/lightning-faucet/internal/ratelimit"
 	"github.com/decred/lightning-faucet/internal/static"
+	"github.com/decred/lightning-faucet/internal/tlscache"
+	"github.com/decred/lightning-faucet/internal/wshub"
 	"github.com/gorilla/mux"
 	"golang.org/x/crypto/acme/autocert"
 )
@@ -90,15 +99,96 @@ func main() {
 	// goroutines required for the faucet to function.
 	faucet.Start(cfg)
 
+	// Spin up the event hub that fans out lnd subscription events to
+	// connected browsers over /ws, and hand it to the faucet so its
+	// channel/invoice goroutines can publish progress as it happens.
+	wsHub := wshub.NewHub()
+	wsStop := make(chan struct{})
+	go wsHub.Run(wsStop)
+	defer close(wsStop)
+	faucet.SetEventHub(wsHub)
+
+	// Build the rate limiter that guards the faucet's action handlers from
+	// being drained by a single abusive client.
+	limiter, err := newRateLimiter(cfg)
+	if err != nil {
+		log.Criticalf("unable to create rate limiter: %v", err)
+		os.Exit(1)
+		return
+	}
+
+	// Build the metrics registry and readiness checker. faucet.Metrics()
+	// and faucet.LndStatus() are updated as the faucet's existing
+	// channel-open/invoice-pay tracking records activity.
+	faucetMetrics := faucet.Metrics()
+	readyChecker := health.NewChecker(faucet, health.Thresholds{
+		MinReadyBalanceSats: cfg.MinReadyBalance,
+		MaxPendingChannels:  cfg.MaxPendingChannels,
+	}, 2*time.Second)
+
+	// Build the auth handler selected by --auth-mode and wire it in ahead
+	// of the route table so every action below requires (or doesn't,
+	// under "none") an authenticated Identity.
+	authHandler, err := newAuthHandler(cfg)
+	if err != nil {
+		log.Criticalf("unable to create auth handler: %v", err)
+		os.Exit(1)
+		return
+	}
+
 	// Create a new mux in order to route a request based on its path to a
-	// dedicated http.Handler.
+	// dedicated http.Handler. The faucet's own actions live on a
+	// subrouter gated by the configured auth mode; the auth provider's
+	// own login/callback endpoints must stay reachable unauthenticated.
 	r := mux.NewRouter()
-	r.HandleFunc("/", faucet.faucetHome).Methods("POST", "GET")
+
+	// Health/readiness/metrics are registered ahead of the auth
+	// middleware so probes and scrapers never need credentials.
+	r.HandleFunc("/healthz", health.LivezHandler).Methods("GET")
+	r.HandleFunc("/readyz", health.ReadyzHandler(readyChecker)).Methods("GET")
+	r.HandleFunc("/metrics", metrics.Handler(faucetMetrics)).Methods("GET")
+
+	gated := r.NewRoute().Subrouter()
+	gated.Use(auth.Middleware(authHandler))
+
+	if oauthHandler, ok := authHandler.(*auth.OAuthHandler); ok {
+		r.HandleFunc("/auth/login", oauthHandler.LoginHandler).Methods("GET")
+		r.HandleFunc("/auth/callback", oauthHandler.CallbackHandler).Methods("GET")
+	}
+
+	// Only a channel-open submission (POST) should charge the
+	// open-channel bucket; a plain page view must stay free.
+	gated.Handle("/", limiter.DynamicMiddleware(func(r *http.Request) string {
+		if r.Method != http.MethodPost {
+			return ""
+		}
+		return "open-channel"
+	}, faucetTemplates)(http.HandlerFunc(faucet.faucetHome))).Methods("POST", "GET")
 	r.HandleFunc("/info", faucet.infoPage).Methods("GET")
+	r.HandleFunc("/limits", limiter.LimitsHandler).Methods("GET")
+	r.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		wshub.ServeWS(wsHub, w, r)
+	})
 
 	// If users disable all actions, then disable the route
 	if !(cfg.DisableGenerateInvoices && cfg.DisablePayInvoices) {
-		r.HandleFunc("/tools", faucet.toolsPage).Methods("POST", "GET")
+		// /tools serves both the generate-invoice and pay-invoice forms
+		// behind one handler, so the action to charge has to be picked
+		// per-request from the submitted form rather than charging both
+		// buckets (or the wrong one) on every request.
+		gated.Handle("/tools", limiter.DynamicMiddleware(func(r *http.Request) string {
+			if r.Method != http.MethodPost {
+				return ""
+			}
+			switch r.FormValue("action") {
+			case "pay-invoice":
+				return "pay-invoice"
+			case "generate-invoice":
+				return "generate-invoice"
+			default:
+				return ""
+			}
+		}, faucetTemplates)(http.HandlerFunc(faucet.toolsPage))).Methods("POST", "GET")
 	}
 
 	// Next create a static file server which will dispatch our static
@@ -109,18 +199,50 @@ func main() {
 		staticHandler := http.StripPrefix("/static/", staticFileServer)
 		r.PathPrefix("/static/").Handler(staticHandler)
 	} else {
+		// Precompute a stable ETag for each asset up front so serving a
+		// request never has to hash the content on the hot path.
+		etags := make(map[string]string, len(static.Assets()))
+		for filepath, content := range static.Assets() {
+			etags[filepath] = fmt.Sprintf(`"%x"`, sha1.Sum(content))
+		}
+
 		// Register all path relative to static files.
 		for filepath := range static.Assets() {
 			r.HandleFunc(fmt.Sprintf("/static%v", filepath),
 				func(w http.ResponseWriter, r *http.Request) {
 					filepath := r.URL.Path[7:]
+					content, ok := static.Assets()[filepath]
+					if !ok {
+						http.NotFound(w, r)
+						return
+					}
 					filepathSlice := strings.Split(filepath, "/")
 					filename := filepathSlice[len(filepathSlice)-1]
-					// Serve correct file from blob.
-					if _, ok := static.Assets()[filepath]; ok {
-						http.ServeContent(w, r, filename, time.Now(),
-							bytes.NewReader(static.Assets()[filepath]))
+
+					etag := etags[filepath]
+					w.Header().Set("Vary", "Accept-Encoding")
+					w.Header().Set("ETag", etag)
+					if hasHashSuffix(filename) {
+						w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+					}
+					if r.Header.Get("If-None-Match") == etag {
+						w.WriteHeader(http.StatusNotModified)
+						return
 					}
+
+					encoding := static.BestEncoding(r.Header.Get("Accept-Encoding"),
+						func(enc string) bool {
+							_, ok := static.Encoded(filepath, enc)
+							return ok
+						})
+					if encoding != "" {
+						encodedContent, _ := static.Encoded(filepath, encoding)
+						w.Header().Set("Content-Encoding", encoding)
+						content = encodedContent
+					}
+
+					http.ServeContent(w, r, filename, time.Now(),
+						bytes.NewReader(content))
 				})
 		}
 	}
@@ -133,10 +255,15 @@ func main() {
 		log.Infof("Listening on %s", cfg.BindAddr)
 		go http.ListenAndServe(cfg.BindAddr, r)
 	} else {
-		// Create a directory cache so the certs we get from Let's
-		// Encrypt are cached locally. This avoids running into their
-		// rate-limiting by requesting too many certs.
-		certCache := autocert.DirCache("certs")
+		// Build the shared, role-aware cert cache so that running more
+		// than one faucet replica behind a load balancer doesn't cause
+		// every replica to race for the same ACME challenge.
+		certCache, err := newCertCache(cfg)
+		if err != nil {
+			log.Criticalf("unable to create cert cache: %v", err)
+			os.Exit(1)
+			return
+		}
 
 		// Create the auto-cert manager which will automatically obtain a
 		// certificate provided by Let's Encrypt.
@@ -181,6 +308,115 @@ func main() {
 	<-c
 }
 
+// hashSuffix matches a content-hash segment in an asset filename, e.g.
+// "app.3f2a9c1b.js", the convention used by the static asset pipeline for
+// cache-busting.
+var hashSuffix = regexp.MustCompile(`\.[0-9a-f]{8,}\.[a-zA-Z0-9]+$`)
+
+// hasHashSuffix reports whether filename carries a content-hash suffix, in
+// which case it's safe to cache forever: a content change always produces a
+// new filename.
+func hasHashSuffix(filename string) bool {
+	return hashSuffix.MatchString(filename)
+}
+
+// newCertCache builds the autocert.Cache used for the Let's Encrypt
+// integration, selecting a leader/follower/auto role via --acme-role so
+// that multiple faucet replicas behind a load balancer share one cache
+// instead of each racing ACME for its own certificate.
+func newCertCache(cfg *config) (autocert.Cache, error) {
+	backend, err := tlscache.NewFilesystemBackend(cfg.ACMECacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open acme cache dir: %v", err)
+	}
+
+	switch cfg.ACMERole {
+	case "auto":
+		holderID, err := tlscache.NewHolderID()
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate election holder id: %v", err)
+		}
+		elector := tlscache.NewFileLockElector(cfg.ACMELeaderLockPath, holderID)
+		stop := make(chan struct{})
+		auto := tlscache.RunElection(elector, cfg.ACMELeaseTTL, stop)
+		cache := tlscache.NewDynamic(backend, auto)
+		tlscache.StartFollowerRefresher(cache, []string{cfg.Domain}, cfg.ACMEFollowerRefresh)
+		return cache, nil
+
+	default:
+		role, err := tlscache.ParseRole(cfg.ACMERole)
+		if err != nil {
+			return nil, err
+		}
+		cache := tlscache.New(backend, role)
+		tlscache.StartFollowerRefresher(cache, []string{cfg.Domain}, cfg.ACMEFollowerRefresh)
+		return cache, nil
+	}
+}
+
+// newAuthHandler builds the auth.AuthHandler selected by --auth-mode.
+func newAuthHandler(cfg *config) (auth.AuthHandler, error) {
+	return auth.New(auth.Config{
+		Mode:           cfg.AuthMode,
+		SharedSecret:   []byte(cfg.AuthSharedSecret),
+		HeaderName:     cfg.AuthHeaderName,
+		TrustedProxies: cfg.AuthTrustedProxies,
+		OAuth: auth.OAuthProvider{
+			ClientID:     cfg.AuthOAuthClientID,
+			ClientSecret: cfg.AuthOAuthClientSecret,
+			AuthURL:      cfg.AuthOAuthAuthURL,
+			TokenURL:     cfg.AuthOAuthTokenURL,
+			UserInfoURL:  cfg.AuthOAuthUserInfoURL,
+			RedirectURL:  cfg.AuthOAuthRedirectURL,
+			Scope:        cfg.AuthOAuthScope,
+		},
+		OAuthCookie:    "faucet_auth",
+		OAuthCookieKey: []byte(cfg.AuthOAuthCookieSecret),
+	})
+}
+
+// newRateLimiter builds a ratelimit.Limiter from the --rate-open-channel,
+// --rate-pay-invoice, --rate-generate-invoice, and
+// --rate-limit-trusted-proxies config options.
+func newRateLimiter(cfg *config) (*ratelimit.Limiter, error) {
+	rates := make(map[string]ratelimit.Rate)
+
+	named := []struct {
+		action string
+		raw    string
+	}{
+		{"open-channel", cfg.RateOpenChannel},
+		{"pay-invoice", cfg.RatePayInvoice},
+		{"generate-invoice", cfg.RateGenerateInvoice},
+	}
+	for _, n := range named {
+		if n.raw == "" {
+			continue
+		}
+		rate, err := ratelimit.ParseRate(n.raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate for %s: %v", n.action, err)
+		}
+		rates[n.action] = rate
+	}
+
+	var trustedProxies []*net.IPNet
+	for _, cidr := range cfg.RateLimitTrustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy CIDR %q: %v",
+				cidr, err)
+		}
+		trustedProxies = append(trustedProxies, ipNet)
+	}
+
+	return ratelimit.New(ratelimit.Config{
+		Rates:          rates,
+		TrustedProxies: trustedProxies,
+		Capacity:       cfg.RateLimitCapacity,
+	}), nil
+}
+
 func init() {
 	// Support TLS 1.3.
 	os.Setenv("GODEBUG", os.Getenv("GODEBUG")+",tls13=1")